@@ -0,0 +1,21 @@
+// Package cache memoizes introspection output so that repeated
+// `hasura metadata reload` cycles don't re-pay the cost of the
+// information_schema joins every time.
+package cache
+
+// Entry is a single cached result together with the content hash of the
+// cheap probe query that produced it, so callers can tell whether the
+// underlying Databricks metadata has changed without re-running the full
+// introspection query.
+type Entry struct {
+	Hash string
+	Data []byte
+}
+
+// Cacher is implemented by both cache backends, in the spirit of xorm's
+// caches.LRUCacher2: a store keyed by an opaque string. Only LRU bounds
+// its size; Disk keeps every entry written to it.
+type Cacher interface {
+	Get(key string) (*Entry, bool)
+	Put(key string, entry *Entry) error
+}
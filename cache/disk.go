@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Disk is a Cacher backend that writes each entry as a JSON file under Dir,
+// with a sidecar file holding the entry's content hash. Get reads and
+// decodes the JSON file as well as the sidecar before returning the entry.
+type Disk struct {
+	Dir string
+}
+
+// NewDisk builds a Disk cache rooted at dir, creating it if necessary.
+func NewDisk(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Disk{Dir: dir}, nil
+}
+
+func (d *Disk) Get(key string) (*Entry, bool) {
+	data, err := os.ReadFile(d.dataPath(key))
+	if err != nil {
+		return nil, false
+	}
+	hash, err := os.ReadFile(d.hashPath(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry.Data); err != nil {
+		return nil, false
+	}
+	entry.Hash = string(hash)
+	return &entry, true
+}
+
+func (d *Disk) Put(key string, entry *Entry) error {
+	data, err := json.Marshal(entry.Data)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(d.dataPath(key), data, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(d.hashPath(key), []byte(entry.Hash), 0644)
+}
+
+func (d *Disk) dataPath(key string) string {
+	return filepath.Join(d.Dir, fingerprint(key)+".json")
+}
+
+func (d *Disk) hashPath(key string) string {
+	return filepath.Join(d.Dir, fingerprint(key)+".sha256")
+}
+
+func fingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
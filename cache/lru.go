@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is an in-process Cacher bounded by both entry count and age. It is
+// safe for concurrent use.
+type LRU struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key       string
+	entry     *Entry
+	expiresAt time.Time
+}
+
+// NewLRU builds an LRU cache. A ttl of 0 means entries never expire by age;
+// maxEntries <= 0 means unbounded by count.
+func NewLRU(ttl time.Duration, maxEntries int) *LRU {
+	return &LRU{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached entry for key if present and not expired.
+func (c *LRU) Get(key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := elem.Value.(*lruItem)
+	if c.ttl > 0 && time.Now().After(item.expiresAt) {
+		c.removeElement(elem)
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	return item.entry, true
+}
+
+// Put stores entry under key, evicting the least recently used entry if the
+// cache is over its configured maxEntries.
+func (c *LRU) Put(key string, entry *Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
+	}
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value = &lruItem{key: key, entry: entry, expiresAt: expiresAt}
+		c.ll.MoveToFront(elem)
+		return nil
+	}
+
+	elem := c.ll.PushFront(&lruItem{key: key, entry: entry, expiresAt: expiresAt})
+	c.items[key] = elem
+
+	if c.maxEntries > 0 {
+		for c.ll.Len() > c.maxEntries {
+			c.removeOldest()
+		}
+	}
+	return nil
+}
+
+func (c *LRU) removeOldest() {
+	elem := c.ll.Back()
+	if elem != nil {
+		c.removeElement(elem)
+	}
+}
+
+func (c *LRU) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	item := elem.Value.(*lruItem)
+	delete(c.items, item.key)
+}
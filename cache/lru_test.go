@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUGetPut(t *testing.T) {
+	c := NewLRU(0, 0)
+
+	if _, ok := c.Get("missing"); ok {
+		t.Fatal("Get on empty cache returned ok=true")
+	}
+
+	entry := &Entry{Hash: "h1", Data: []byte("a")}
+	if err := c.Put("key", entry); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	got, ok := c.Get("key")
+	if !ok {
+		t.Fatal("Get after Put returned ok=false")
+	}
+	if got.Hash != "h1" || string(got.Data) != "a" {
+		t.Errorf("Get returned %+v, want %+v", got, entry)
+	}
+}
+
+func TestLRUEvictsOldestOverCapacity(t *testing.T) {
+	c := NewLRU(0, 2)
+
+	c.Put("a", &Entry{Hash: "a"})
+	c.Put("b", &Entry{Hash: "b"})
+	c.Put("c", &Entry{Hash: "c"})
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("oldest entry 'a' should have been evicted once maxEntries was exceeded")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Error("'b' should still be cached")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("'c' should still be cached")
+	}
+}
+
+func TestLRUTouchOnGetProtectsFromEviction(t *testing.T) {
+	c := NewLRU(0, 2)
+
+	c.Put("a", &Entry{Hash: "a"})
+	c.Put("b", &Entry{Hash: "b"})
+	c.Get("a") // touch a, making b the least recently used
+	c.Put("c", &Entry{Hash: "c"})
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("'b' should have been evicted after 'a' was touched")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("'a' should still be cached after being touched")
+	}
+}
+
+func TestLRUExpiresByTTL(t *testing.T) {
+	c := NewLRU(time.Millisecond, 0)
+
+	c.Put("key", &Entry{Hash: "h"})
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("key"); ok {
+		t.Error("entry should have expired after its TTL elapsed")
+	}
+}
+
+func TestLRUZeroTTLNeverExpires(t *testing.T) {
+	c := NewLRU(0, 0)
+
+	c.Put("key", &Entry{Hash: "h"})
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("key"); !ok {
+		t.Error("entry should not expire when ttl is 0")
+	}
+}
@@ -1,94 +1,60 @@
 // cli/main.go
 
+//go:generate go run . schema -output ../introspection/schema.json
+
 package main
 
 import (
-	"bytes"
+	"context"
 	"database/sql"
-	"encoding/json"
 	"flag"
 	"fmt"
 	_ "github.com/databricks/databricks-sql-go"
+	"github.com/hasura/ndc-databricks/cache"
+	"github.com/hasura/ndc-databricks/introspection"
+	"net/url"
 	"os"
 	"runtime/debug"
+	"strings"
 )
 
-// ErrorLocation provides context about where an error occurred
-type ErrorLocation struct {
-	Function string
-	Message  string
-	Err      error
-}
+// regexpList is a repeatable flag.Value collecting one string per -flag
+// occurrence, e.g. -include-tables 'a' -include-tables 'b'.
+type regexpList []string
 
-func (e *ErrorLocation) Error() string {
-	return fmt.Sprintf("[%s] %s: %v", e.Function, e.Message, e.Err)
+func (r *regexpList) String() string {
+	return fmt.Sprint([]string(*r))
 }
 
-func debugTableAccess(db *sql.DB) error {
-	// Check all accessible catalogs
-	catalogQuery := `
-    SELECT DISTINCT catalog_name
-    FROM information_schema.catalogs
-    ORDER BY catalog_name`
-
-	fmt.Println("\nAccessible Catalogs:")
-	rows, err := db.Query(catalogQuery)
-	if err != nil {
-		return fmt.Errorf("failed to query catalogs: %v", err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var catalogName string
-		if err := rows.Scan(&catalogName); err != nil {
-			return fmt.Errorf("failed to scan catalog row: %v", err)
-		}
-		fmt.Printf("- %s\n", catalogName)
-	}
-
-	// Check all accessible schemas
-	schemaQuery := `
-    SELECT DISTINCT table_catalog, table_schema
-    FROM information_schema.tables
-    ORDER BY table_catalog, table_schema`
-
-	fmt.Println("\nAccessible Schemas:")
-	rows, err = db.Query(schemaQuery)
-	if err != nil {
-		return fmt.Errorf("failed to query schemas: %v", err)
-	}
-	defer rows.Close()
+func (r *regexpList) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
 
-	for rows.Next() {
-		var catalog, schema string
-		if err := rows.Scan(&catalog, &schema); err != nil {
-			return fmt.Errorf("failed to scan schema row: %v", err)
-		}
-		fmt.Printf("- %s.%s\n", catalog, schema)
-	}
+// sessionVarList is a repeatable flag.Value collecting key=value pairs,
+// e.g. -session-var spark.sql.ansi.enabled=true.
+type sessionVarList []introspection.SessionVar
 
-	// Check all accessible tables
-	tableQuery := `
-    SELECT table_catalog, table_schema, table_name, table_type
-    FROM information_schema.tables
-    ORDER BY table_catalog, table_schema, table_name`
+func (s *sessionVarList) String() string {
+	return fmt.Sprint([]introspection.SessionVar(*s))
+}
 
-	fmt.Println("\nAccessible Tables:")
-	rows, err = db.Query(tableQuery)
-	if err != nil {
-		return fmt.Errorf("failed to query tables: %v", err)
+func (s *sessionVarList) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("-session-var must be key=value, got %q", value)
 	}
-	defer rows.Close()
+	*s = append(*s, introspection.SessionVar{Key: key, Value: val})
+	return nil
+}
 
-	for rows.Next() {
-		var catalog, schema, name, tableType string
-		if err := rows.Scan(&catalog, &schema, &name, &tableType); err != nil {
-			return fmt.Errorf("failed to scan table row: %v", err)
-		}
-		fmt.Printf("- %s.%s.%s (%s)\n", catalog, schema, name, tableType)
+// dsnHost extracts the workspace host from dsn for use as a cache key
+// prefix, falling back to the raw DSN if it doesn't parse as a URL.
+func dsnHost(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Host != "" {
+		return u.Host
 	}
-
-	return nil
+	return dsn
 }
 
 func main() {
@@ -100,6 +66,11 @@ func main() {
 		}
 	}()
 
+	if len(os.Args) > 1 && os.Args[1] == "schema" {
+		runSchema(os.Args[2:])
+		return
+	}
+
 	dsn := os.Getenv("DATABRICKS_DSN")
 	if dsn == "" {
 		panic("No connection string found. Set the DATABRICKS_DSN environment variable.")
@@ -116,208 +87,102 @@ func main() {
 		panic(err)
 	}
 
-	// Add this debug section
-	fmt.Println("=== DEBUG INFORMATION ===")
-	if err = debugTableAccess(db); err != nil {
-		fmt.Printf("Debug error: %v\n", err)
-	}
-	fmt.Println("=======================")
-
-	catalog := flag.String("catalog", "", "Optional: Specific catalog to introspect")
-	schema := flag.String("schema", "", "Optional: Specific schema to introspect (default: default)")
-	output := flag.String("output", "", "Optional: Output JSON file path")
+	output := flag.String("output", "", "Optional: Output file path (NDJSON, one catalog/schema/table-set per line)")
+	depthFlag := flag.String("depth", "all", "How deep to introspect: catalogs, schemas, tables, or all (columns+constraints)")
+	concurrency := flag.Int("concurrency", 4, "Number of catalogs to walk concurrently")
+	constraints := flag.Bool("constraints", true, "Resolve primary/foreign keys at -depth=all (requires INFORMATION_SCHEMA access to constraint views)")
+	var includeSchemas, excludeSchemas, includeTables, excludeTables regexpList
+	flag.Var(&includeSchemas, "include-schemas", "Regexp matched against catalog.schema; repeatable. A schema is kept iff it matches at least one -include-schemas (or none are set) and no -exclude-schemas")
+	flag.Var(&excludeSchemas, "exclude-schemas", "Regexp matched against catalog.schema; repeatable")
+	flag.Var(&includeTables, "include-tables", "Regexp matched against catalog.schema.table; repeatable. A table is kept iff it matches at least one -include-tables (or none are set) and no -exclude-tables")
+	flag.Var(&excludeTables, "exclude-tables", "Regexp matched against catalog.schema.table; repeatable")
+	cacheDir := flag.String("cache-dir", "", "Optional: cache introspection results as JSON files under this directory instead of in-process")
+	cacheTTL := flag.Duration("cache-ttl", 0, "Optional: expire in-process cache entries after this long (ignored with -cache-dir)")
+	cacheMaxEntries := flag.Int("cache-max-entries", 0, "Optional: bound the in-process cache to this many entries, 0 = unbounded (ignored with -cache-dir)")
+	validate := flag.Bool("validate", false, "Validate each result against the JSON Schema (see the schema subcommand) before writing it")
+	initSQL := flag.String("init-sql", "", "Optional: path to a file of semicolon-separated statements (e.g. USE CATALOG x;) to run on a pinned connection before introspecting")
+	var sessionVars sessionVarList
+	flag.Var(&sessionVars, "session-var", "key=value, becomes `SET key=value` on the pinned connection; repeatable")
 	flag.Parse()
 
-	query := buildIntrospectionQuery(*catalog, *schema)
-	println("Query: ", query)
-	jsonStr, err := executeQuery(db, query)
+	depth, err := introspection.ParseDepth(*depthFlag)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Pretty print the JSON
-	var prettyJSON bytes.Buffer
-	if err := json.Indent(&prettyJSON, []byte(jsonStr), "", "  "); err != nil {
-		fmt.Printf("Error formatting JSON: %v\n", err)
+	filter, err := introspection.NewFilter(includeSchemas, excludeSchemas, includeTables, excludeTables)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if *output != "" {
-		err = os.WriteFile(*output, prettyJSON.Bytes(), 0644)
+	var cacher cache.Cacher
+	switch {
+	case *cacheDir != "":
+		disk, err := cache.NewDisk(*cacheDir)
 		if err != nil {
-			fmt.Printf("Error writing to file: %v\n", err)
+			fmt.Printf("Error creating cache dir: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Results written to %s\n", *output)
-	} else {
-		fmt.Println(prettyJSON.String())
+		cacher = disk
+	case *cacheTTL > 0 || *cacheMaxEntries > 0:
+		cacher = cache.NewLRU(*cacheTTL, *cacheMaxEntries)
 	}
-}
-
-func buildIntrospectionQuery(catalog, schema string) string {
-	baseQuery := `
-    WITH column_info AS (
-        SELECT
-            t.table_catalog,
-            t.table_schema,
-            t.table_name,
-            t.table_type,
-            map_from_entries(array_agg(
-                struct(
-                    c.column_name as key,
-                    struct(
-                        c.column_name as name,
-                        UPPER(c.data_type) as scalarType,
-                        c.is_nullable = 'YES' as nullable
-                    ) as value
-                )
-            )) as columns,
-            null as primary_keys
-        FROM information_schema.tables t
-        JOIN information_schema.columns c
-            ON t.table_catalog = c.table_catalog
-            AND t.table_schema = c.table_schema
-            AND t.table_name = c.table_name
-        WHERE t.table_schema != 'information_schema'
-    `
 
-	if catalog != "" {
-		baseQuery += fmt.Sprintf("\nAND t.table_catalog = '%s'", catalog)
-	}
-	if schema != "" {
-		print("Schema: ", schema)
-		baseQuery += fmt.Sprintf("\nAND t.table_schema = '%s'", schema)
+	var validator *introspection.Validator
+	if *validate {
+		validator, err = introspection.NewValidator()
+		if err != nil {
+			fmt.Printf("Error compiling JSON Schema: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	baseQuery += `
-        GROUP BY t.table_catalog, t.table_schema, t.table_name, t.table_type
-    )
-    SELECT to_json(
-        map_from_entries(
-            array_agg(
-                struct(
-                    CONCAT(table_schema, '.', table_name) as key,  -- Include schema in key
-                    struct(
-                        table_catalog as physicalCatalog,
-                        table_schema as physicalSchema,
-                        '' as catalog,
-                        table_schema as schema,
-                        table_name as name,
-                        columns as columns,
-                        primary_keys as primaryKeys,
-                        array() as exportedKeys
-                    ) as value
-                )
-            )
-        )
-    ) as tables
-    FROM column_info`
-
-	return baseQuery
-}
-
-// func buildIntrospectionQuery(catalog, schema string) string {
-// 	// Default to 'default' schema if none provided
-// 	if schema == "" {
-// 		schema = "default"
-// 	}
-
-// 	baseQuery := `
-// 	WITH column_info AS (
-// 		SELECT
-// 			t.table_catalog,
-// 			t.table_schema,
-// 			t.table_name,
-// 			t.table_type,
-// 			map_from_entries(array_agg(
-// 				struct(
-// 					c.column_name as key,
-// 					struct(
-// 						c.column_name as name,
-// 						UPPER(c.data_type) as scalarType,
-// 						c.is_nullable = 'YES' as nullable
-// 					) as value
-// 				)
-
-// 			)) as columns,
-// 			array_remove(collect_list(
-// 				CASE
-// 					WHEN tc.constraint_type = 'PRIMARY KEY' THEN c.column_name
-// 					ELSE NULL
-// 				END
-// 			), null) as primary_keys
-// 		FROM information_schema.tables t
-// 		JOIN information_schema.columns c
-// 			ON t.table_catalog = c.table_catalog
-// 			AND t.table_schema = c.table_schema
-// 			AND t.table_name = c.table_name
-// 		LEFT JOIN information_schema.table_constraints tc
-// 			ON t.table_catalog = tc.table_catalog
-// 			AND t.table_schema = tc.table_schema
-// 			AND t.table_name = tc.table_name
-// 			AND tc.constraint_type = 'PRIMARY KEY'
-// 		LEFT JOIN information_schema.key_column_usage kcu
-// 			ON tc.constraint_catalog = kcu.constraint_catalog
-// 			AND tc.constraint_schema = kcu.constraint_schema
-// 			AND tc.constraint_name = kcu.constraint_name
-// 			AND c.column_name = kcu.column_name
-// 	`
-
-// 	if catalog != "" {
-// 		baseQuery += fmt.Sprintf("\nWHERE t.table_catalog = '%s'", catalog)
-// 		if schema != "" {
-// 			baseQuery += fmt.Sprintf("\nAND t.table_schema = '%s'", schema)
-// 		}
-// 	} else if schema != "" {
-// 		baseQuery += fmt.Sprintf("\nWHERE t.table_schema = '%s'", schema)
-// 	}
-
-// 	baseQuery += `
-// 		GROUP BY t.table_catalog, t.table_schema, t.table_name, t.table_type
-// 	)
-// 	SELECT to_json(
-// 		map_from_entries(
-// 			array_agg(
-// 				struct(
-// 					table_name as key,
-// 					struct(
-// 						table_catalog as physicalCatalog,
-// 						table_schema as physicalSchema,
-// 						'' as catalog,
-// 						table_schema as schema,
-// 						table_name as name,
-// 						columns as columns,
-// 						primary_keys as primaryKeys,
-// 						array() as exportedKeys
-// 					) as value
-// 				)
-
-// 			)
-// 		)
-// 	) as tables
-// 	FROM column_info`
-
-// 	return baseQuery
-// }
-
-func executeQuery(db *sql.DB, query string) (string, error) {
-	const funcName = "executeQuery"
-	var jsonStr string
-
-	err := db.QueryRow(query).Scan(&jsonStr)
-	if err != nil {
-		return "", wrapError(funcName, "failed to execute query", err)
+	var out *os.File
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			fmt.Printf("Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer out.Close()
+	} else {
+		out = os.Stdout
 	}
 
-	return jsonStr, nil
-}
+	var queryer introspection.Queryer = db
+	if *initSQL != "" || len(sessionVars) > 0 {
+		conn, err := introspection.InitSession(context.Background(), db, *initSQL, sessionVars)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer conn.Close()
+		queryer = conn
+		// A *sql.Conn serves one statement at a time, so the concurrent
+		// per-catalog walk can't share it; fall back to walking catalogs
+		// one at a time so the session state from -init-sql/-session-var
+		// stays valid for every query.
+		*concurrency = 1
+	}
+
+	opts := introspection.Options{
+		Depth:       depth,
+		Concurrency: *concurrency,
+		Filter:      filter,
+		Constraints: *constraints,
+		Cache:       cacher,
+		Host:        dsnHost(dsn),
+		Validator:   validator,
+	}
+
+	if err := introspection.Run(queryer, opts, out); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
-// wrapError adds function context to errors
-func wrapError(function, message string, err error) error {
-	return &ErrorLocation{
-		Function: function,
-		Message:  message,
-		Err:      err,
+	if *output != "" {
+		fmt.Printf("Results written to %s\n", *output)
 	}
 }
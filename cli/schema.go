@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hasura/ndc-databricks/introspection"
+)
+
+// runSchema implements the `schema` subcommand: it emits the JSON Schema
+// describing introspection's -output shape, optionally to a file.
+func runSchema(args []string) {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	output := fs.String("output", "", "Optional: write the JSON Schema to this file instead of stdout")
+	fs.Parse(args)
+
+	var out *os.File
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Printf("Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	} else {
+		out = os.Stdout
+	}
+
+	if err := introspection.WriteJSONSchema(out); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output != "" {
+		fmt.Printf("Schema written to %s\n", *output)
+	}
+}
@@ -0,0 +1,101 @@
+package introspection
+
+import (
+	"context"
+	"fmt"
+)
+
+// ResolveConstraints populates PrimaryKeys and ExportedKeys on each table in
+// tables. It is only invoked at DepthAll.
+//
+// Databricks only reliably exposes constraint metadata from the owning
+// catalog's information_schema, so both queries are scoped to catalog and
+// the results are stitched onto tables (keyed by "schema.table") here in Go,
+// rather than attempting a single cross-catalog SparkSQL query.
+func ResolveConstraints(db Queryer, catalog string, tables map[string]TableInfo) error {
+	const funcName = "ResolveConstraints"
+
+	if err := resolvePrimaryKeys(db, catalog, tables); err != nil {
+		return wrapError(funcName, fmt.Sprintf("failed to resolve primary keys for %s", catalog), err)
+	}
+	if err := resolveExportedKeys(db, catalog, tables); err != nil {
+		return wrapError(funcName, fmt.Sprintf("failed to resolve exported keys for %s", catalog), err)
+	}
+	return nil
+}
+
+func resolvePrimaryKeys(db Queryer, catalog string, tables map[string]TableInfo) error {
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT tc.table_schema, tc.table_name, kcu.column_name
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+			ON tc.constraint_catalog = kcu.constraint_catalog
+			AND tc.constraint_schema = kcu.constraint_schema
+			AND tc.constraint_name = kcu.constraint_name
+		WHERE tc.constraint_type = 'PRIMARY KEY'
+		AND tc.table_catalog = ?
+		ORDER BY tc.table_schema, tc.table_name, kcu.ordinal_position`, catalog)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schema, table, column string
+		if err := rows.Scan(&schema, &table, &column); err != nil {
+			return err
+		}
+		key := schema + "." + table
+		info, ok := tables[key]
+		if !ok {
+			continue
+		}
+		info.PrimaryKeys = append(info.PrimaryKeys, column)
+		tables[key] = info
+	}
+	return rows.Err()
+}
+
+func resolveExportedKeys(db Queryer, catalog string, tables map[string]TableInfo) error {
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT
+			fk.table_catalog, fk.table_schema, fk.table_name, fk.column_name,
+			pk.table_schema, pk.table_name, pk.column_name,
+			rc.constraint_name, rc.update_rule, rc.delete_rule
+		FROM information_schema.referential_constraints rc
+		JOIN information_schema.key_column_usage fk
+			ON rc.constraint_catalog = fk.constraint_catalog
+			AND rc.constraint_schema = fk.constraint_schema
+			AND rc.constraint_name = fk.constraint_name
+		JOIN information_schema.key_column_usage pk
+			ON rc.unique_constraint_catalog = pk.constraint_catalog
+			AND rc.unique_constraint_schema = pk.constraint_schema
+			AND rc.unique_constraint_name = pk.constraint_name
+			AND fk.ordinal_position = pk.ordinal_position
+		WHERE rc.constraint_catalog = ?`, catalog)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key ExportedKey
+		var pkSchema, pkTable string
+		if err := rows.Scan(
+			&key.ForeignCatalog, &key.ForeignSchema, &key.ForeignTable, &key.ForeignColumn,
+			&pkSchema, &pkTable, &key.PKColumn,
+			&key.ConstraintName, &key.UpdateRule, &key.DeleteRule,
+		); err != nil {
+			return err
+		}
+
+		pkKey := pkSchema + "." + pkTable
+		info, ok := tables[pkKey]
+		if !ok {
+			continue
+		}
+		info.ExportedKeys = append(info.ExportedKeys, key)
+		tables[pkKey] = info
+	}
+	return rows.Err()
+}
@@ -0,0 +1,53 @@
+// Package introspection walks a Databricks Unity Catalog workspace and
+// produces the table/column metadata consumed by the connector's config
+// loader.
+package introspection
+
+import "fmt"
+
+// ObjectDepth controls how far the introspection pipeline descends into the
+// catalog hierarchy, mirroring the ObjectDepth levels used by the ADBC
+// Snowflake driver's GetObjects API.
+type ObjectDepth int
+
+const (
+	// DepthCatalogs lists catalogs only.
+	DepthCatalogs ObjectDepth = iota
+	// DepthSchemas lists catalogs and schemas.
+	DepthSchemas
+	// DepthTables lists catalogs, schemas and tables (no columns).
+	DepthTables
+	// DepthAll lists catalogs, schemas, tables, columns and constraints.
+	DepthAll
+)
+
+// ParseDepth converts the `-depth` flag value into an ObjectDepth.
+func ParseDepth(s string) (ObjectDepth, error) {
+	switch s {
+	case "catalogs":
+		return DepthCatalogs, nil
+	case "schemas":
+		return DepthSchemas, nil
+	case "tables":
+		return DepthTables, nil
+	case "all":
+		return DepthAll, nil
+	default:
+		return 0, fmt.Errorf("invalid depth %q: must be one of catalogs, schemas, tables, all", s)
+	}
+}
+
+func (d ObjectDepth) String() string {
+	switch d {
+	case DepthCatalogs:
+		return "catalogs"
+	case DepthSchemas:
+		return "schemas"
+	case DepthTables:
+		return "tables"
+	case DepthAll:
+		return "all"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,112 @@
+package introspection
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Filter decides which schemas and tables an introspection run descends
+// into. Patterns are evaluated in Go against the fully qualified name of
+// the object (catalog.schema for schemas, catalog.schema.table for tables)
+// after the metadata rows have already come back from the warehouse,
+// rather than being pushed down into SQL.
+type Filter struct {
+	includeSchemas []*regexp.Regexp
+	excludeSchemas []*regexp.Regexp
+	includeTables  []*regexp.Regexp
+	excludeTables  []*regexp.Regexp
+}
+
+// NewFilter compiles the given patterns. Any patterns may be empty.
+func NewFilter(includeSchemas, excludeSchemas, includeTables, excludeTables []string) (*Filter, error) {
+	var f Filter
+	var err error
+
+	if f.includeSchemas, err = compilePatterns(includeSchemas); err != nil {
+		return nil, err
+	}
+	if f.excludeSchemas, err = compilePatterns(excludeSchemas); err != nil {
+		return nil, err
+	}
+	if f.includeTables, err = compilePatterns(includeTables); err != nil {
+		return nil, err
+	}
+	if f.excludeTables, err = compilePatterns(excludeTables); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// AllowsSchema reports whether catalog.schema should be descended into.
+func (f *Filter) AllowsSchema(catalog, schema string) bool {
+	if f == nil {
+		return true
+	}
+	return matches(catalog+"."+schema, f.includeSchemas, f.excludeSchemas)
+}
+
+// AllowsTable reports whether catalog.schema.table should be kept.
+func (f *Filter) AllowsTable(catalog, schema, table string) bool {
+	if f == nil {
+		return true
+	}
+	return matches(catalog+"."+schema+"."+table, f.includeTables, f.excludeTables)
+}
+
+// Fingerprint returns a deterministic string identifying this filter's
+// pattern set, suitable for inclusion in a cache key.
+func (f *Filter) Fingerprint() string {
+	if f == nil {
+		return ""
+	}
+	return fmt.Sprintf("is=%s,es=%s,it=%s,et=%s",
+		patternStrings(f.includeSchemas), patternStrings(f.excludeSchemas),
+		patternStrings(f.includeTables), patternStrings(f.excludeTables))
+}
+
+func patternStrings(patterns []*regexp.Regexp) []string {
+	strs := make([]string, len(patterns))
+	for i, re := range patterns {
+		strs[i] = re.String()
+	}
+	return strs
+}
+
+// matches implements the canal precedence rule: an object is kept iff it
+// matches at least one include pattern (or no includes are set) and does
+// not match any exclude pattern.
+func matches(name string, includes, excludes []*regexp.Regexp) bool {
+	if len(includes) > 0 {
+		matched := false
+		for _, re := range includes {
+			if re.MatchString(name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for _, re := range excludes {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	return true
+}
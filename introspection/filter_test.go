@@ -0,0 +1,121 @@
+package introspection
+
+import "testing"
+
+func TestFilterAllowsSchema(t *testing.T) {
+	tests := []struct {
+		name           string
+		nilFilter      bool
+		includeSchemas []string
+		excludeSchemas []string
+		catalog        string
+		schema         string
+		want           bool
+	}{
+		{
+			name:      "nil filter allows everything",
+			nilFilter: true,
+			want:      true,
+		},
+		{
+			name:   "no patterns allows everything",
+			schema: "public",
+			want:   true,
+		},
+		{
+			name:           "matches include",
+			includeSchemas: []string{`^main\.public$`},
+			catalog:        "main",
+			schema:         "public",
+			want:           true,
+		},
+		{
+			name:           "does not match any include",
+			includeSchemas: []string{`^main\.public$`},
+			catalog:        "main",
+			schema:         "private",
+			want:           false,
+		},
+		{
+			name:           "exclude wins over include",
+			includeSchemas: []string{`^main\..*$`},
+			excludeSchemas: []string{`^main\.private$`},
+			catalog:        "main",
+			schema:         "private",
+			want:           false,
+		},
+		{
+			name:           "exclude with no includes set",
+			excludeSchemas: []string{`^main\.private$`},
+			catalog:        "main",
+			schema:         "public",
+			want:           true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter *Filter
+			if !tt.nilFilter {
+				f, err := NewFilter(tt.includeSchemas, tt.excludeSchemas, nil, nil)
+				if err != nil {
+					t.Fatalf("NewFilter: %v", err)
+				}
+				filter = f
+			}
+			if got := filter.AllowsSchema(tt.catalog, tt.schema); got != tt.want {
+				t.Errorf("AllowsSchema(%q, %q) = %v, want %v", tt.catalog, tt.schema, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterAllowsTable(t *testing.T) {
+	filter, err := NewFilter(nil, nil, []string{`\.orders$`, `\.customers$`}, []string{`^main\.staging\..*$`})
+	if err != nil {
+		t.Fatalf("NewFilter: %v", err)
+	}
+
+	tests := []struct {
+		catalog, schema, table string
+		want                   bool
+	}{
+		{"main", "public", "orders", true},
+		{"main", "public", "customers", true},
+		{"main", "public", "line_items", false},
+		{"main", "staging", "orders", false},
+	}
+
+	for _, tt := range tests {
+		if got := filter.AllowsTable(tt.catalog, tt.schema, tt.table); got != tt.want {
+			t.Errorf("AllowsTable(%q, %q, %q) = %v, want %v", tt.catalog, tt.schema, tt.table, got, tt.want)
+		}
+	}
+}
+
+func TestFilterFingerprintStableAndDistinct(t *testing.T) {
+	a, err := NewFilter([]string{"a"}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewFilter: %v", err)
+	}
+	b, err := NewFilter([]string{"a"}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewFilter: %v", err)
+	}
+	c, err := NewFilter([]string{"b"}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewFilter: %v", err)
+	}
+
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("identical patterns produced different fingerprints: %q vs %q", a.Fingerprint(), b.Fingerprint())
+	}
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Errorf("different patterns produced the same fingerprint: %q", a.Fingerprint())
+	}
+
+	var nilFilter *Filter
+	if nilFilter.Fingerprint() != "" {
+		t.Errorf("nil Filter.Fingerprint() = %q, want empty string", nilFilter.Fingerprint())
+	}
+}
@@ -0,0 +1,91 @@
+package introspection
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONSchema returns the draft 2020-12 JSON Schema describing a single
+// Result as written to -output: the shape the connector's config loader
+// expects, previously only implicit in the SparkSQL struct(...) projection
+// in buildTablesQuery.
+func JSONSchema() map[string]any {
+	return map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     "https://github.com/hasura/ndc-databricks/introspection/schema.json",
+		"title":   "Introspection result",
+		"type":    "object",
+		"properties": map[string]any{
+			"catalog": map[string]any{"type": "string"},
+			"schema":  map[string]any{"type": "string"},
+			"tables": map[string]any{
+				"type":                 "object",
+				"description":          "Keyed by \"schema.table\".",
+				"additionalProperties": tableInfoSchema(),
+			},
+		},
+		"required": []string{"catalog"},
+	}
+}
+
+func tableInfoSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"physicalCatalog": map[string]any{"type": "string"},
+			"physicalSchema":  map[string]any{"type": "string"},
+			"catalog":         map[string]any{"type": "string"},
+			"schema":          map[string]any{"type": "string"},
+			"name":            map[string]any{"type": "string"},
+			"columns": map[string]any{
+				"type":                 "object",
+				"additionalProperties": columnInfoSchema(),
+			},
+			"primaryKeys": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+			"exportedKeys": map[string]any{
+				"type":  "array",
+				"items": exportedKeySchema(),
+			},
+		},
+		"required": []string{"physicalCatalog", "physicalSchema", "catalog", "schema", "name"},
+	}
+}
+
+func columnInfoSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":       map[string]any{"type": "string"},
+			"scalarType": map[string]any{"type": "string"},
+			"nullable":   map[string]any{"type": "boolean"},
+		},
+		"required": []string{"name", "scalarType", "nullable"},
+	}
+}
+
+func exportedKeySchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"foreign_catalog": map[string]any{"type": "string"},
+			"foreign_schema":  map[string]any{"type": "string"},
+			"foreign_table":   map[string]any{"type": "string"},
+			"foreign_column":  map[string]any{"type": "string"},
+			"pk_column":       map[string]any{"type": "string"},
+			"constraint_name": map[string]any{"type": "string"},
+			"update_rule":     map[string]any{"type": "string"},
+			"delete_rule":     map[string]any{"type": "string"},
+		},
+		"required": []string{"foreign_catalog", "foreign_schema", "foreign_table", "foreign_column", "pk_column", "constraint_name"},
+	}
+}
+
+// WriteJSONSchema writes the indented JSON Schema document to w.
+func WriteJSONSchema(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(JSONSchema())
+}
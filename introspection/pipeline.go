@@ -0,0 +1,239 @@
+package introspection
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hasura/ndc-databricks/cache"
+)
+
+// Options configures a single introspection run.
+type Options struct {
+	// Depth controls how far the pipeline descends (catalogs, schemas,
+	// tables or all).
+	Depth ObjectDepth
+	// Concurrency bounds how many catalogs are walked in parallel once the
+	// pipeline descends past DepthCatalogs. Values <= 0 are treated as 1.
+	Concurrency int
+	// Filter restricts which schemas and tables are descended into. A nil
+	// Filter keeps everything.
+	Filter *Filter
+	// Constraints controls whether primary/foreign keys are resolved at
+	// DepthAll. Disable it when the SQL warehouse doesn't grant
+	// INFORMATION_SCHEMA access to the constraint views.
+	Constraints bool
+	// Cache, if set, memoizes per-schema results keyed by (Host, catalog,
+	// schema, Depth, Constraints, Filter). A cheap probe query decides
+	// whether a cached entry is still fresh; see ProbeHash.
+	Cache cache.Cacher
+	// Host identifies the workspace for cache keys, e.g. the DSN's host.
+	Host string
+	// Validator, if set, checks every written Result against JSONSchema
+	// before it is written, so drift between the SQL projection and the
+	// schema is caught at generation time.
+	Validator *Validator
+}
+
+// Run walks the workspace according to opts and writes one JSON object per
+// line (NDJSON) to w as each catalog/schema/table finishes, instead of
+// buffering the whole workspace in memory before emitting anything.
+func Run(db Queryer, opts Options, w io.Writer) error {
+	const funcName = "Run"
+
+	catalogs, err := ListCatalogs(db)
+	if err != nil {
+		return wrapError(funcName, "failed to resolve catalogs", err)
+	}
+
+	enc := json.NewEncoder(w)
+	var writeMu sync.Mutex
+	write := func(v any) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return enc.Encode(v)
+	}
+
+	if opts.Depth == DepthCatalogs {
+		for _, catalog := range catalogs {
+			if err := write(CatalogEntry{Catalog: catalog}); err != nil {
+				return wrapError(funcName, "failed to write catalog entry", err)
+			}
+		}
+		return nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var firstErr error
+	recordErr := func(err error) {
+		errMu.Lock()
+		defer errMu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for _, catalog := range catalogs {
+		catalog := catalog
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := processCatalog(db, catalog, opts, write); err != nil {
+				recordErr(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return wrapError(funcName, "failed to walk workspace", firstErr)
+	}
+	return nil
+}
+
+func processCatalog(db Queryer, catalog string, opts Options, write func(any) error) error {
+	const funcName = "processCatalog"
+
+	allSchemas, err := ListSchemas(db, catalog)
+	if err != nil {
+		return wrapError(funcName, "failed to list schemas for "+catalog, err)
+	}
+
+	var schemas []string
+	for _, schema := range allSchemas {
+		if opts.Filter.AllowsSchema(catalog, schema) {
+			schemas = append(schemas, schema)
+		}
+	}
+
+	if opts.Depth == DepthSchemas {
+		return write(CatalogEntry{Catalog: catalog, Schemas: schemas})
+	}
+
+	for _, schema := range schemas {
+		tables, err := resolveTables(db, catalog, schema, opts)
+		if err != nil {
+			return wrapError(funcName, "failed to resolve tables for "+catalog+"."+schema, err)
+		}
+		if tables == nil {
+			// Every table in this schema was filtered out.
+			continue
+		}
+
+		result := Result{Catalog: catalog, Schema: schema, Tables: tables}
+		if opts.Validator != nil {
+			if err := opts.Validator.Validate(result); err != nil {
+				return wrapError(funcName, "result failed validation for "+catalog+"."+schema, err)
+			}
+		}
+		if err := write(result); err != nil {
+			return wrapError(funcName, "failed to write result", err)
+		}
+	}
+	return nil
+}
+
+// resolveTables returns the (possibly cached) tables for catalog.schema, or
+// nil if every table in the schema was filtered out.
+func resolveTables(db Queryer, catalog, schema string, opts Options) (map[string]TableInfo, error) {
+	if opts.Cache == nil {
+		return fetchTables(db, catalog, schema, opts)
+	}
+
+	key := cacheKey(opts.Host, catalog, schema, opts)
+	hash, err := ProbeHash(db, catalog, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry, ok := opts.Cache.Get(key); ok && entry.Hash == hash {
+		var tables map[string]TableInfo
+		if err := json.Unmarshal(entry.Data, &tables); err != nil {
+			return nil, err
+		}
+		return tables, nil
+	}
+
+	tables, err := fetchTables(db, catalog, schema, opts)
+	if err != nil {
+		return nil, err
+	}
+	if tables == nil {
+		return nil, nil
+	}
+
+	if data, err := json.Marshal(tables); err == nil {
+		_ = opts.Cache.Put(key, &cache.Entry{Hash: hash, Data: data})
+	}
+	return tables, nil
+}
+
+// fetchTables runs the actual information_schema queries: a cheap table-name
+// listing to apply the table filter, then (if anything survives and the
+// pipeline was asked for DepthAll) the columns/constraints join. At
+// DepthTables the table names themselves are the whole answer, so the
+// columns join is skipped entirely.
+func fetchTables(db Queryer, catalog, schema string, opts Options) (map[string]TableInfo, error) {
+	tableNames, err := ListTableNames(db, catalog, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var allowed []string
+	for _, table := range tableNames {
+		if opts.Filter.AllowsTable(catalog, schema, table) {
+			allowed = append(allowed, table)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, nil
+	}
+
+	if opts.Depth == DepthTables {
+		tables := make(map[string]TableInfo, len(allowed))
+		for _, table := range allowed {
+			tables[schema+"."+table] = TableInfo{
+				PhysicalCatalog: catalog,
+				PhysicalSchema:  schema,
+				Schema:          schema,
+				Name:            table,
+			}
+		}
+		return tables, nil
+	}
+
+	tables, err := ListTables(db, catalog, schema, allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	// Constraint resolution (primaryKeys/exportedKeys) only happens at
+	// DepthAll; see ResolveConstraints.
+	if opts.Depth == DepthAll && opts.Constraints {
+		if err := ResolveConstraints(db, catalog, tables); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// cacheKey must include everything that changes what resolveTables returns
+// for a given catalog.schema, not just the inputs to ProbeHash: Depth
+// decides whether columns are fetched at all, and Constraints decides
+// whether primaryKeys/exportedKeys are populated at DepthAll. Omitting
+// either lets a cheap run (e.g. -depth=tables or -constraints=false)
+// populate an entry that a later, more detailed run would wrongly reuse.
+func cacheKey(host, catalog, schema string, opts Options) string {
+	return fmt.Sprintf("%s|%s|%s|depth=%s|constraints=%t|%s",
+		host, catalog, schema, opts.Depth, opts.Constraints, opts.Filter.Fingerprint())
+}
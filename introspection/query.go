@@ -0,0 +1,227 @@
+package introspection
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Queryer is implemented by both *sql.DB and *sql.Conn, so the functions in
+// this package can run either against the connection pool or against a
+// single pinned connection when session state (USE CATALOG, SET ...) needs
+// to persist across statements; see InitSession.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// ErrorLocation provides context about where an error occurred.
+type ErrorLocation struct {
+	Function string
+	Message  string
+	Err      error
+}
+
+func (e *ErrorLocation) Error() string {
+	return fmt.Sprintf("[%s] %s: %v", e.Function, e.Message, e.Err)
+}
+
+// wrapError adds function context to errors.
+func wrapError(function, message string, err error) error {
+	return &ErrorLocation{Function: function, Message: message, Err: err}
+}
+
+// ListCatalogs returns every catalog visible to the current connection,
+// excluding the system information_schema catalog.
+func ListCatalogs(db Queryer) ([]string, error) {
+	const funcName = "ListCatalogs"
+
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT DISTINCT catalog_name
+		FROM information_schema.catalogs
+		WHERE catalog_name != 'information_schema'
+		ORDER BY catalog_name`)
+	if err != nil {
+		return nil, wrapError(funcName, "failed to query catalogs", err)
+	}
+	defer rows.Close()
+
+	var catalogs []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, wrapError(funcName, "failed to scan catalog row", err)
+		}
+		catalogs = append(catalogs, name)
+	}
+	return catalogs, rows.Err()
+}
+
+// ListSchemas returns every schema within catalog, excluding
+// information_schema itself.
+func ListSchemas(db Queryer, catalog string) ([]string, error) {
+	const funcName = "ListSchemas"
+
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT DISTINCT schema_name
+		FROM information_schema.schemata
+		WHERE catalog_name = ? AND schema_name != 'information_schema'
+		ORDER BY schema_name`, catalog)
+	if err != nil {
+		return nil, wrapError(funcName, fmt.Sprintf("failed to query schemas in %s", catalog), err)
+	}
+	defer rows.Close()
+
+	var schemas []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, wrapError(funcName, "failed to scan schema row", err)
+		}
+		schemas = append(schemas, name)
+	}
+	return schemas, rows.Err()
+}
+
+// ListTableNames cheaply lists the tables in catalog.schema without joining
+// columns, so callers can apply a Filter before paying for the heavier
+// columns/constraints queries.
+func ListTableNames(db Queryer, catalog, schema string) ([]string, error) {
+	const funcName = "ListTableNames"
+
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_catalog = ? AND table_schema = ?
+		ORDER BY table_name`, catalog, schema)
+	if err != nil {
+		return nil, wrapError(funcName, fmt.Sprintf("failed to list table names in %s.%s", catalog, schema), err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, wrapError(funcName, "failed to scan table name row", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// ListTables fetches columns for the given tables in catalog.schema,
+// returning the same shape the connector's config loader expects.
+// PrimaryKeys and ExportedKeys are left unpopulated here; they are filled in
+// by ResolveConstraints for callers that asked for DepthAll. tableNames must
+// be non-empty; callers that want every table should list them first with
+// ListTableNames.
+func ListTables(db Queryer, catalog, schema string, tableNames []string) (map[string]TableInfo, error) {
+	const funcName = "ListTables"
+
+	jsonStr, err := executeQuery(db, buildTablesQuery(tableNames), catalog, schema)
+	if err != nil {
+		return nil, wrapError(funcName, fmt.Sprintf("failed to list tables in %s.%s", catalog, schema), err)
+	}
+	if jsonStr == "" {
+		return map[string]TableInfo{}, nil
+	}
+
+	var tables map[string]TableInfo
+	if err := json.Unmarshal([]byte(jsonStr), &tables); err != nil {
+		return nil, wrapError(funcName, "failed to decode tables JSON", err)
+	}
+	return tables, nil
+}
+
+func buildTablesQuery(tableNames []string) string {
+	return fmt.Sprintf(`
+    WITH column_info AS (
+        SELECT
+            t.table_catalog,
+            t.table_schema,
+            t.table_name,
+            t.table_type,
+            map_from_entries(array_agg(
+                struct(
+                    c.column_name as key,
+                    struct(
+                        c.column_name as name,
+                        UPPER(c.data_type) as scalarType,
+                        c.is_nullable = 'YES' as nullable
+                    ) as value
+                )
+            )) as columns
+        FROM information_schema.tables t
+        JOIN information_schema.columns c
+            ON t.table_catalog = c.table_catalog
+            AND t.table_schema = c.table_schema
+            AND t.table_name = c.table_name
+        WHERE t.table_catalog = ?
+        AND t.table_schema = ?
+        AND t.table_name IN (%s)
+        GROUP BY t.table_catalog, t.table_schema, t.table_name, t.table_type
+    )
+    SELECT to_json(
+        map_from_entries(
+            array_agg(
+                struct(
+                    CONCAT(table_schema, '.', table_name) as key,
+                    struct(
+                        table_catalog as physicalCatalog,
+                        table_schema as physicalSchema,
+                        '' as catalog,
+                        table_schema as schema,
+                        table_name as name,
+                        columns as columns
+                    ) as value
+                )
+            )
+        )
+    ) as tables
+    FROM column_info`, quotedList(tableNames))
+}
+
+func quotedList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + strings.ReplaceAll(name, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// ProbeHash runs a cheap query over catalog.schema's last-altered timestamps
+// and returns a hash of the result, so a cache can tell whether the heavier
+// columns/constraints joins would return anything different without
+// actually running them.
+func ProbeHash(db Queryer, catalog, schema string) (string, error) {
+	const funcName = "ProbeHash"
+
+	var probe sql.NullString
+	err := db.QueryRowContext(context.Background(), `
+		SELECT CAST(max(last_altered) AS STRING)
+		FROM information_schema.tables
+		WHERE table_catalog = ? AND table_schema = ?`, catalog, schema).Scan(&probe)
+	if err != nil {
+		return "", wrapError(funcName, fmt.Sprintf("failed to probe %s.%s", catalog, schema), err)
+	}
+
+	sum := sha256.Sum256([]byte(probe.String))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func executeQuery(db Queryer, query string, args ...any) (string, error) {
+	const funcName = "executeQuery"
+	var jsonStr string
+
+	err := db.QueryRowContext(context.Background(), query, args...).Scan(&jsonStr)
+	if err != nil {
+		return "", wrapError(funcName, "failed to execute query", err)
+	}
+
+	return jsonStr, nil
+}
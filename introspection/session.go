@@ -0,0 +1,114 @@
+package introspection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SessionVar becomes a `SET key=value` statement executed against the
+// pinned connection before introspection begins.
+type SessionVar struct {
+	Key   string
+	Value string
+}
+
+// InitSession pins a dedicated connection for the lifetime of an
+// introspection run and executes, in order, the statements in initSQLPath
+// (if any) followed by one SET per sessionVar. This lets callers whose org
+// policy requires `USE CATALOG x; USE SCHEMA y;` (or other session-scoped
+// settings) before unqualified queries get a session that persists across
+// the whole pipeline, rather than a fresh pooled connection per query. The
+// caller must Close() the returned connection when done.
+func InitSession(ctx context.Context, db *sql.DB, initSQLPath string, sessionVars []SessionVar) (*sql.Conn, error) {
+	const funcName = "InitSession"
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, wrapError(funcName, "failed to pin a connection", err)
+	}
+
+	if initSQLPath != "" {
+		script, err := os.ReadFile(initSQLPath)
+		if err != nil {
+			conn.Close()
+			return nil, wrapError(funcName, "failed to read -init-sql", err)
+		}
+		for _, stmt := range SplitStatements(string(script)) {
+			if _, err := conn.ExecContext(ctx, stmt); err != nil {
+				conn.Close()
+				return nil, wrapError(funcName, fmt.Sprintf("failed to execute init statement %q", stmt), err)
+			}
+		}
+	}
+
+	for _, v := range sessionVars {
+		stmt := fmt.Sprintf("SET %s=%s", v.Key, v.Value)
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			conn.Close()
+			return nil, wrapError(funcName, fmt.Sprintf("failed to set session var %s", v.Key), err)
+		}
+	}
+
+	return conn, nil
+}
+
+// SplitStatements splits script into individual SQL statements on top-level
+// semicolons, since databricks-sql-go accepts one statement per Exec. It
+// respects '...', "...", and `...` string literals, -- line comments and
+// /* */ block comments so semicolons inside them aren't treated as
+// separators.
+func SplitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	runes := []rune(script)
+	n := len(runes)
+
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < n; {
+		c := runes[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote := c
+			current.WriteRune(c)
+			i++
+			for i < n {
+				current.WriteRune(runes[i])
+				closing := runes[i] == quote
+				i++
+				if closing {
+					break
+				}
+			}
+		case c == '-' && i+1 < n && runes[i+1] == '-':
+			for i < n && runes[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < n && runes[i+1] == '*':
+			i += 2
+			for i+1 < n && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i += 2
+			if i > n {
+				i = n
+			}
+		case c == ';':
+			flush()
+			i++
+		default:
+			current.WriteRune(c)
+			i++
+		}
+	}
+	flush()
+	return statements
+}
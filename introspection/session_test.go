@@ -0,0 +1,79 @@
+package introspection
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "simple statements",
+			script: "USE CATALOG a; USE SCHEMA b;",
+			want:   []string{"USE CATALOG a", "USE SCHEMA b"},
+		},
+		{
+			name:   "no trailing semicolon",
+			script: "SET spark.sql.ansi.enabled=true",
+			want:   []string{"SET spark.sql.ansi.enabled=true"},
+		},
+		{
+			name:   "semicolon inside single-quoted literal",
+			script: "SET foo='a;b'; SET bar=1;",
+			want:   []string{"SET foo='a;b'", "SET bar=1"},
+		},
+		{
+			name:   "semicolon inside double-quoted literal",
+			script: `SET foo="a;b";`,
+			want:   []string{`SET foo="a;b"`},
+		},
+		{
+			name:   "escaped quote via doubling inside literal",
+			script: "SET foo='it''s; fine';",
+			want:   []string{"SET foo='it''s; fine'"},
+		},
+		{
+			name:   "backtick-quoted identifier with semicolon",
+			script: "SELECT `a;b` FROM t;",
+			want:   []string{"SELECT `a;b` FROM t"},
+		},
+		{
+			name:   "line comment hides a semicolon",
+			script: "USE CATALOG a; -- comment with a ; in it\nUSE SCHEMA b;",
+			want:   []string{"USE CATALOG a", "USE SCHEMA b"},
+		},
+		{
+			name:   "block comment hides a semicolon",
+			script: "USE CATALOG a; /* comment ; with ; semicolons */ USE SCHEMA b;",
+			want:   []string{"USE CATALOG a", "USE SCHEMA b"},
+		},
+		{
+			name:   "unterminated block comment is consumed to end of script",
+			script: "USE CATALOG a; /* never closed",
+			want:   []string{"USE CATALOG a"},
+		},
+		{
+			name:   "blank statements and whitespace are dropped",
+			script: "  ;  USE CATALOG a ;  ; \n\t;",
+			want:   []string{"USE CATALOG a"},
+		},
+		{
+			name:   "empty script",
+			script: "",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitStatements(tt.script)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitStatements(%q) = %#v, want %#v", tt.script, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package introspection
+
+// ColumnInfo describes a single column of a table.
+type ColumnInfo struct {
+	Name       string `json:"name"`
+	ScalarType string `json:"scalarType"`
+	Nullable   bool   `json:"nullable"`
+}
+
+// TableInfo describes a single table (or view) discovered during
+// introspection. Columns, PrimaryKeys and ExportedKeys are only populated
+// when the pipeline is run at DepthAll.
+type TableInfo struct {
+	PhysicalCatalog string                `json:"physicalCatalog"`
+	PhysicalSchema  string                `json:"physicalSchema"`
+	Catalog         string                `json:"catalog"`
+	Schema          string                `json:"schema"`
+	Name            string                `json:"name"`
+	Columns         map[string]ColumnInfo `json:"columns,omitempty"`
+	PrimaryKeys     []string              `json:"primaryKeys,omitempty"`
+	ExportedKeys    []ExportedKey         `json:"exportedKeys,omitempty"`
+}
+
+// ExportedKey describes a foreign key relationship pointing at this table,
+// i.e. a row of information_schema.referential_constraints joined back to
+// key_column_usage on both sides of the constraint.
+type ExportedKey struct {
+	ForeignCatalog string `json:"foreign_catalog"`
+	ForeignSchema  string `json:"foreign_schema"`
+	ForeignTable   string `json:"foreign_table"`
+	ForeignColumn  string `json:"foreign_column"`
+	PKColumn       string `json:"pk_column"`
+	ConstraintName string `json:"constraint_name"`
+	UpdateRule     string `json:"update_rule"`
+	DeleteRule     string `json:"delete_rule"`
+}
+
+// CatalogEntry is a catalog name together with its child schemas, used when
+// streaming results incrementally rather than collecting a single Tables map.
+type CatalogEntry struct {
+	Catalog string   `json:"catalog"`
+	Schemas []string `json:"schemas,omitempty"`
+}
+
+// Result is the top-level shape written to -output. Tables is keyed by
+// "schema.table" within a single catalog/schema fetch, matching the shape
+// the connector's config loader already expects.
+type Result struct {
+	Catalog string               `json:"catalog"`
+	Schema  string               `json:"schema,omitempty"`
+	Tables  map[string]TableInfo `json:"tables,omitempty"`
+}
@@ -0,0 +1,54 @@
+package introspection
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Validator checks Result values against JSONSchema.
+type Validator struct {
+	schema *jsonschema.Schema
+}
+
+// NewValidator compiles JSONSchema once for reuse across many Validate
+// calls.
+func NewValidator() (*Validator, error) {
+	const funcName = "NewValidator"
+
+	schemaBytes, err := json.Marshal(JSONSchema())
+	if err != nil {
+		return nil, wrapError(funcName, "failed to marshal JSON Schema", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("schema.json", bytes.NewReader(schemaBytes)); err != nil {
+		return nil, wrapError(funcName, "failed to load JSON Schema", err)
+	}
+	schema, err := compiler.Compile("schema.json")
+	if err != nil {
+		return nil, wrapError(funcName, "failed to compile JSON Schema", err)
+	}
+	return &Validator{schema: schema}, nil
+}
+
+// Validate checks that v matches JSONSchema, catching drift between the
+// SQL projection and the schema at generation time instead of at connector
+// startup.
+func (val *Validator) Validate(v any) error {
+	const funcName = "Validate"
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return wrapError(funcName, "failed to marshal value", err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return wrapError(funcName, "failed to decode value", err)
+	}
+	if err := val.schema.Validate(doc); err != nil {
+		return wrapError(funcName, "result failed schema validation", err)
+	}
+	return nil
+}